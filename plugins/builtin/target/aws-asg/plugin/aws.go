@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// asgAPI is the subset of *autoscaling.Client this plugin calls, allowing
+// tests to substitute a fake implementation instead of making real AWS API
+// calls. *autoscaling.Client satisfies this interface.
+type asgAPI interface {
+	DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+	DescribeScalingActivities(ctx context.Context, params *autoscaling.DescribeScalingActivitiesInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeScalingActivitiesOutput, error)
+	DescribeTags(ctx context.Context, params *autoscaling.DescribeTagsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeTagsOutput, error)
+	SetDesiredCapacity(ctx context.Context, params *autoscaling.SetDesiredCapacityInput, optFns ...func(*autoscaling.Options)) (*autoscaling.SetDesiredCapacityOutput, error)
+	TerminateInstanceInAutoScalingGroup(ctx context.Context, params *autoscaling.TerminateInstanceInAutoScalingGroupInput, optFns ...func(*autoscaling.Options)) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error)
+	RecordLifecycleActionHeartbeat(ctx context.Context, params *autoscaling.RecordLifecycleActionHeartbeatInput, optFns ...func(*autoscaling.Options)) (*autoscaling.RecordLifecycleActionHeartbeatOutput, error)
+	CompleteLifecycleAction(ctx context.Context, params *autoscaling.CompleteLifecycleActionInput, optFns ...func(*autoscaling.Options)) (*autoscaling.CompleteLifecycleActionOutput, error)
+}
+
+// regionFromConfig returns the region a Scale/Status call should operate
+// against, falling back to configValueRegionDefault when the operator has
+// not set configKeyRegion on the policy's target config.
+func regionFromConfig(config map[string]string) string {
+	if region, ok := config[configKeyRegion]; ok && region != "" {
+		return region
+	}
+	return configValueRegionDefault
+}
+
+// clientsForRegion returns the AWS clients for the passed region, lazily
+// constructing and caching them on first use. This allows a single plugin
+// instance to drive ASGs across multiple regions without reconnecting on
+// every call.
+func (t *TargetPlugin) clientsForRegion(ctx context.Context, region string) (*autoscaling.Client, *ec2.Client, error) {
+
+	t.clientsLock.Lock()
+	defer t.clientsLock.Unlock()
+
+	if asgClient, ok := t.asgClients[region]; ok {
+		return asgClient, t.ec2Clients[region], nil
+	}
+
+	opts := []func(*awsConfig.LoadOptions) error{awsConfig.WithRegion(region)}
+
+	accessID, idOK := t.config[configKeyAccessID]
+	secretKey, keyOK := t.config[configKeySecretKey]
+	if idOK && keyOK {
+		opts = append(opts, awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			accessID, secretKey, t.config[configKeySessionToken])))
+	}
+
+	cfg, err := awsConfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load default AWS config for region %s: %v", region, err)
+	}
+
+	asgClient := autoscaling.NewFromConfig(cfg)
+	ec2Client := ec2.NewFromConfig(cfg)
+
+	t.asgClients[region] = asgClient
+	t.ec2Clients[region] = ec2Client
+
+	return asgClient, ec2Client, nil
+}
+
+// resolveASGNames returns the names of the ASG(s) to operate against for
+// this call. configKeyASGNames, a comma-separated list, takes precedence
+// and fans a single action out across all listed sibling ASGs; otherwise
+// configKeyASGName is used directly if set, and failing that every ASG
+// tagged for the current policy is resolved and fanned out across in the
+// same way, rather than silently acting on only one of them.
+func (t *TargetPlugin) resolveASGNames(ctx context.Context, client asgAPI, config map[string]string) ([]string, error) {
+
+	if namesCSV, ok := config[configKeyASGNames]; ok && namesCSV != "" {
+		names := strings.Split(namesCSV, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		return names, nil
+	}
+
+	if name, ok := config[configKeyASGName]; ok && name != "" {
+		return []string{name}, nil
+	}
+
+	return t.resolveASGNamesByTag(ctx, client, config)
+}
+
+// resolveASGNamesByTag returns the names of every ASG tagged with
+// configValueASGTagKey matching the policy ID driving the current action,
+// analogous to how the Kubernetes cluster-autoscaler AWS provider discovers
+// ASGs by tag. More than one match is expected when a policy is meant to
+// manage a fleet of sibling ASGs without operators having to list them all
+// explicitly via configKeyASGNames.
+func (t *TargetPlugin) resolveASGNamesByTag(ctx context.Context, client asgAPI, config map[string]string) ([]string, error) {
+
+	policyID, ok := config[configKeyPolicyID]
+	if !ok || policyID == "" {
+		return nil, fmt.Errorf("one of %s, %s or %s is required", configKeyASGNames, configKeyASGName, configKeyPolicyID)
+	}
+
+	resp, err := client.DescribeTags(ctx, &autoscaling.DescribeTagsInput{
+		Filters: []autoscalingtypes.Filter{
+			{Name: aws.String("key"), Values: []string{configValueASGTagKey}},
+			{Name: aws.String("value"), Values: []string{policyID}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe AWS ASG tags: %v", err)
+	}
+
+	if len(resp.Tags) == 0 {
+		return nil, fmt.Errorf("no ASG found tagged %s=%s", configValueASGTagKey, policyID)
+	}
+
+	names := make([]string, 0, len(resp.Tags))
+	for _, tag := range resp.Tags {
+		names = append(names, *tag.ResourceId)
+	}
+
+	return names, nil
+}
+
+// describeASG returns the AWS AutoScalingGroup matching the passed name. An
+// error is returned if the group cannot be found, or more than one match is
+// returned by the API, which should not be possible given names are unique.
+func (t *TargetPlugin) describeASG(ctx context.Context, client asgAPI, asgName string) (*autoscalingtypes.AutoScalingGroup, error) {
+
+	resp, err := client.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{asgName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if l := len(resp.AutoScalingGroups); l != 1 {
+		return nil, fmt.Errorf("expected 1 AutoScalingGroup, got %d", l)
+	}
+
+	return &resp.AutoScalingGroups[0], nil
+}
+
+// describeActivities returns the scaling activities for the named ASG,
+// ordered with the most recent activity first.
+func (t *TargetPlugin) describeActivities(ctx context.Context, client asgAPI, asgName string, nextToken *string) ([]autoscalingtypes.Activity, error) {
+
+	resp, err := client.DescribeScalingActivities(ctx, &autoscaling.DescribeScalingActivitiesInput{
+		AutoScalingGroupName: aws.String(asgName),
+		NextToken:            nextToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Activities, nil
+}
+
+// scaleOut updates the ASG DesiredCapacity to match the requested count.
+func (t *TargetPlugin) scaleOut(ctx context.Context, client asgAPI, asg *autoscalingtypes.AutoScalingGroup, count int64) error {
+
+	_, err := client.SetDesiredCapacity(ctx, &autoscaling.SetDesiredCapacityInput{
+		AutoScalingGroupName: asg.AutoScalingGroupName,
+		DesiredCapacity:      aws.Int32(int32(count)),
+		HonorCooldown:        aws.Bool(false),
+	})
+	return err
+}
+
+// scaleIn drains and removes num nodes from the ASG. Termination candidates
+// are selected using EC2 instance details (lifecycle, launch time, and
+// node_selector tag filters); scaleInUtils is given the resulting candidate
+// count rather than the raw requested num, so the number of Nomad nodes
+// drained always matches the number of instances actually terminated below,
+// even when node_selector or ASG membership leaves fewer candidates than
+// requested. scaleInUtils still chooses which Nomad nodes to drain using its
+// own node-selection strategy: it has no way to target the specific
+// instances selected here, so drain/terminate coordination is by count, not
+// by node identity. When configKeyLifecycleHookName is set, termination is
+// coordinated with a preconfigured EC2_INSTANCE_TERMINATING lifecycle hook
+// instead of completing immediately.
+func (t *TargetPlugin) scaleIn(ctx context.Context, asgClient asgAPI, ec2Client *ec2.Client, asg *autoscalingtypes.AutoScalingGroup, num int64, config map[string]string) error {
+
+	ids, err := t.selectTerminationCandidates(ctx, ec2Client, asg, num, config[configKeyNodeSelector])
+	if err != nil {
+		return err
+	}
+
+	if hookName := config[configKeyLifecycleHookName]; hookName != "" {
+		return t.scaleInWithLifecycleHook(ctx, asgClient, asg, ids, hookName, config)
+	}
+
+	if len(ids) == 0 {
+		t.logger.Warn("no termination candidates matched node_selector, skipping scale-in",
+			"asg_name", *asg.AutoScalingGroupName, "requested", num, "node_selector", config[configKeyNodeSelector])
+		return nil
+	}
+
+	if err := t.scaleInUtils.ScaleIn(ctx, len(ids), config); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := asgClient.TerminateInstanceInAutoScalingGroup(ctx, &autoscaling.TerminateInstanceInAutoScalingGroupInput{
+			InstanceId:                     aws.String(id),
+			ShouldDecrementDesiredCapacity: aws.Bool(true),
+		}); err != nil {
+			return fmt.Errorf("failed to terminate instance %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+// fakeASGClient implements asgAPI with configurable per-method behaviour,
+// so resolveASGNames/resolveASGNamesByTag can be tested without making real
+// AWS calls.
+type fakeASGClient struct {
+	describeTagsFunc func(ctx context.Context, in *autoscaling.DescribeTagsInput) (*autoscaling.DescribeTagsOutput, error)
+}
+
+func (f *fakeASGClient) DescribeAutoScalingGroups(ctx context.Context, in *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	return nil, nil
+}
+
+func (f *fakeASGClient) DescribeScalingActivities(ctx context.Context, in *autoscaling.DescribeScalingActivitiesInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	return nil, nil
+}
+
+func (f *fakeASGClient) DescribeTags(ctx context.Context, in *autoscaling.DescribeTagsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeTagsOutput, error) {
+	return f.describeTagsFunc(ctx, in)
+}
+
+func (f *fakeASGClient) SetDesiredCapacity(ctx context.Context, in *autoscaling.SetDesiredCapacityInput, optFns ...func(*autoscaling.Options)) (*autoscaling.SetDesiredCapacityOutput, error) {
+	return nil, nil
+}
+
+func (f *fakeASGClient) TerminateInstanceInAutoScalingGroup(ctx context.Context, in *autoscaling.TerminateInstanceInAutoScalingGroupInput, optFns ...func(*autoscaling.Options)) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error) {
+	return nil, nil
+}
+
+func (f *fakeASGClient) RecordLifecycleActionHeartbeat(ctx context.Context, in *autoscaling.RecordLifecycleActionHeartbeatInput, optFns ...func(*autoscaling.Options)) (*autoscaling.RecordLifecycleActionHeartbeatOutput, error) {
+	return nil, nil
+}
+
+func (f *fakeASGClient) CompleteLifecycleAction(ctx context.Context, in *autoscaling.CompleteLifecycleActionInput, optFns ...func(*autoscaling.Options)) (*autoscaling.CompleteLifecycleActionOutput, error) {
+	return nil, nil
+}
+
+func TestTargetPlugin_resolveASGNames(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		describeTags  func(ctx context.Context, in *autoscaling.DescribeTagsInput) (*autoscaling.DescribeTagsOutput, error)
+		expectedNames []string
+		expectErr     bool
+	}{
+		{
+			name:          "asg_names takes precedence",
+			config:        map[string]string{configKeyASGNames: "asg-a, asg-b", configKeyASGName: "asg-c"},
+			expectedNames: []string{"asg-a", "asg-b"},
+		},
+		{
+			name:          "asg_name used directly",
+			config:        map[string]string{configKeyASGName: "asg-a"},
+			expectedNames: []string{"asg-a"},
+		},
+		{
+			name:   "tag lookup returns every match",
+			config: map[string]string{configKeyPolicyID: "policy-1"},
+			describeTags: func(ctx context.Context, in *autoscaling.DescribeTagsInput) (*autoscaling.DescribeTagsOutput, error) {
+				return &autoscaling.DescribeTagsOutput{
+					Tags: []autoscalingtypes.TagDescription{
+						{ResourceId: aws.String("asg-a")},
+						{ResourceId: aws.String("asg-b")},
+					},
+				}, nil
+			},
+			expectedNames: []string{"asg-a", "asg-b"},
+		},
+		{
+			name:   "tag lookup with no matches errors",
+			config: map[string]string{configKeyPolicyID: "policy-1"},
+			describeTags: func(ctx context.Context, in *autoscaling.DescribeTagsInput) (*autoscaling.DescribeTagsOutput, error) {
+				return &autoscaling.DescribeTagsOutput{}, nil
+			},
+			expectErr: true,
+		},
+		{
+			name:      "no asg_names, asg_name, or policy ID errors",
+			config:    map[string]string{},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &TargetPlugin{logger: hclog.NewNullLogger()}
+			client := &fakeASGClient{describeTagsFunc: tc.describeTags}
+
+			names, err := target.resolveASGNames(context.Background(), client, tc.config)
+
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(names) != len(tc.expectedNames) {
+				t.Fatalf("expected %v, got %v", tc.expectedNames, names)
+			}
+			for i := range names {
+				if names[i] != tc.expectedNames[i] {
+					t.Fatalf("expected %v, got %v", tc.expectedNames, names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+// weightedTargets splits want across asgs proportionally to each ASG's
+// current DesiredCapacity, so a single scaling action fans out across
+// sibling ASGs (configKeyASGNames) in the same ratio they're already
+// running at. The final ASG absorbs any rounding remainder so the targets
+// always sum to want.
+func weightedTargets(asgs []*autoscalingtypes.AutoScalingGroup, totalDesired, want int64) []int64 {
+
+	targets := make([]int64, len(asgs))
+	if len(asgs) == 0 {
+		return targets
+	}
+
+	if len(asgs) == 1 {
+		targets[0] = want
+		return targets
+	}
+
+	if totalDesired == 0 {
+		// No ASG has any capacity to weight by yet (e.g. a fresh multi-region
+		// bootstrap), so split evenly instead of dumping everything onto the
+		// first ASG. The last ASG absorbs any rounding remainder.
+		base := want / int64(len(asgs))
+		for i := range targets {
+			targets[i] = base
+		}
+		targets[len(targets)-1] += want - base*int64(len(asgs))
+		return targets
+	}
+
+	var assigned int64
+	for i := 0; i < len(asgs)-1; i++ {
+		share := want * *asgs[i].DesiredCapacity / totalDesired
+		targets[i] = share
+		assigned += share
+	}
+	targets[len(asgs)-1] = want - assigned
+
+	return targets
+}
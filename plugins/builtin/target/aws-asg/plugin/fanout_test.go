@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func TestWeightedTargets(t *testing.T) {
+	asgWithCapacity := func(desired int64) *autoscalingtypes.AutoScalingGroup {
+		return &autoscalingtypes.AutoScalingGroup{DesiredCapacity: aws.Int64(desired)}
+	}
+
+	testCases := []struct {
+		name         string
+		asgs         []*autoscalingtypes.AutoScalingGroup
+		totalDesired int64
+		want         int64
+		expected     []int64
+	}{
+		{
+			name:         "single ASG gets everything",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(3)},
+			totalDesired: 3,
+			want:         10,
+			expected:     []int64{10},
+		},
+		{
+			name:         "even split",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(5), asgWithCapacity(5)},
+			totalDesired: 10,
+			want:         10,
+			expected:     []int64{5, 5},
+		},
+		{
+			name:         "proportional split",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(1), asgWithCapacity(3)},
+			totalDesired: 4,
+			want:         8,
+			expected:     []int64{2, 6},
+		},
+		{
+			name:         "remainder absorbed by last ASG",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(1), asgWithCapacity(1), asgWithCapacity(1)},
+			totalDesired: 3,
+			want:         10,
+			expected:     []int64{3, 3, 4},
+		},
+		{
+			name:         "zero total desired splits evenly",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(0), asgWithCapacity(0)},
+			totalDesired: 0,
+			want:         6,
+			expected:     []int64{3, 3},
+		},
+		{
+			name:         "zero total desired remainder absorbed by last ASG",
+			asgs:         []*autoscalingtypes.AutoScalingGroup{asgWithCapacity(0), asgWithCapacity(0), asgWithCapacity(0)},
+			totalDesired: 0,
+			want:         7,
+			expected:     []int64{2, 2, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := weightedTargets(tc.asgs, tc.totalDesired, tc.want)
+
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+
+			var sum int64
+			for i := range actual {
+				if actual[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, actual)
+				}
+				sum += actual[i]
+			}
+			if sum != tc.want {
+				t.Fatalf("expected targets to sum to %v, got %v", tc.want, sum)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+const (
+	// configKeyLifecycleHookName names a preconfigured
+	// autoscaling:EC2_INSTANCE_TERMINATING lifecycle hook that holds
+	// selected instances in the Terminating:Wait state so Nomad can drain
+	// them before AWS actually removes them from the ASG.
+	configKeyLifecycleHookName = "lifecycle_hook_name"
+
+	// configKeyHeartbeatTimeout overrides, in seconds, how often the
+	// background heartbeat goroutine calls RecordLifecycleActionHeartbeat
+	// while a drain is in progress. Defaults to
+	// configValueHeartbeatTimeoutDefault.
+	configKeyHeartbeatTimeout = "heartbeat_timeout"
+
+	// configValueHeartbeatTimeoutDefault is the assumed lifecycle hook
+	// heartbeat timeout, in seconds, used when configKeyHeartbeatTimeout is
+	// not supplied.
+	configValueHeartbeatTimeoutDefault = 300
+
+	// minHeartbeatInterval is the smallest interval the heartbeat goroutine
+	// will run at, regardless of how low an operator sets
+	// configKeyHeartbeatTimeout.
+	minHeartbeatInterval = time.Second
+
+	lifecycleActionResultContinue = "CONTINUE"
+	lifecycleActionResultAbandon  = "ABANDON"
+)
+
+// scaleInWithLifecycleHook terminates the selected instances via a
+// preconfigured EC2_INSTANCE_TERMINATING lifecycle hook. AWS holds each
+// instance in Terminating:Wait once termination is requested, giving Nomad
+// time to drain the node; a background goroutine records heartbeats for the
+// hook while the drain runs, and the lifecycle action is completed with
+// CONTINUE on a successful drain or ABANDON otherwise so AWS proceeds with
+// termination regardless.
+func (t *TargetPlugin) scaleInWithLifecycleHook(ctx context.Context, asgClient asgAPI, asg *autoscalingtypes.AutoScalingGroup, ids []string, hookName string, config map[string]string) error {
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, id := range ids {
+		if _, err := asgClient.TerminateInstanceInAutoScalingGroup(ctx, &autoscaling.TerminateInstanceInAutoScalingGroupInput{
+			InstanceId:                     aws.String(id),
+			ShouldDecrementDesiredCapacity: aws.Bool(true),
+		}); err != nil {
+			return fmt.Errorf("failed to start termination of instance %s: %v", id, err)
+		}
+	}
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+
+	go t.heartbeatLifecycleAction(heartbeatCtx, asgClient, asg, hookName, ids, heartbeatIntervalFromConfig(config))
+
+	drainErr := t.scaleInUtils.ScaleIn(ctx, len(ids), config)
+	stopHeartbeat()
+
+	result := lifecycleActionResult(drainErr)
+
+	for _, id := range ids {
+		if _, err := asgClient.CompleteLifecycleAction(ctx, &autoscaling.CompleteLifecycleActionInput{
+			AutoScalingGroupName:  asg.AutoScalingGroupName,
+			LifecycleHookName:     aws.String(hookName),
+			InstanceId:            aws.String(id),
+			LifecycleActionResult: aws.String(result),
+		}); err != nil {
+			t.logger.Warn("failed to complete lifecycle action", "instance_id", id, "error", err)
+		}
+	}
+
+	return drainErr
+}
+
+// lifecycleActionResult returns the LifecycleActionResult to report for a
+// drain that finished with the passed error: CONTINUE lets AWS proceed with
+// the termination it already had queued, ABANDON tells AWS to proceed
+// immediately without waiting out the rest of the hook's timeout.
+func lifecycleActionResult(drainErr error) string {
+	if drainErr != nil {
+		return lifecycleActionResultAbandon
+	}
+	return lifecycleActionResultContinue
+}
+
+// heartbeatLifecycleAction periodically records a lifecycle action
+// heartbeat for each instance so a long running drain doesn't exceed the
+// hook's heartbeat_timeout and have AWS proceed with termination before
+// Nomad has finished draining the node.
+func (t *TargetPlugin) heartbeatLifecycleAction(ctx context.Context, asgClient asgAPI, asg *autoscalingtypes.AutoScalingGroup, hookName string, ids []string, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, id := range ids {
+				if _, err := asgClient.RecordLifecycleActionHeartbeat(ctx, &autoscaling.RecordLifecycleActionHeartbeatInput{
+					AutoScalingGroupName: asg.AutoScalingGroupName,
+					LifecycleHookName:    aws.String(hookName),
+					InstanceId:           aws.String(id),
+				}); err != nil {
+					t.logger.Warn("failed to record lifecycle action heartbeat", "instance_id", id, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// heartbeatIntervalFromConfig returns how often the heartbeat goroutine
+// should run: half the configured (or default) heartbeat_timeout, so at
+// least one heartbeat lands comfortably within each timeout window. The
+// result is never less than minHeartbeatInterval, since time.NewTicker
+// panics on a non-positive duration and a heartbeat_timeout below a few
+// seconds would otherwise produce one.
+func heartbeatIntervalFromConfig(config map[string]string) time.Duration {
+
+	timeout := configValueHeartbeatTimeoutDefault
+	if raw, ok := config[configKeyHeartbeatTimeout]; ok && raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			timeout = parsed
+		}
+	}
+
+	interval := time.Duration(timeout/2) * time.Second
+	if interval < minHeartbeatInterval {
+		return minHeartbeatInterval
+	}
+
+	return interval
+}
@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+// heartbeatCountingClient embeds fakeASGClient and counts
+// RecordLifecycleActionHeartbeat calls, so heartbeatLifecycleAction's
+// ticking/cancellation behaviour can be observed without a real AWS call.
+type heartbeatCountingClient struct {
+	fakeASGClient
+	heartbeats int32
+}
+
+func (h *heartbeatCountingClient) RecordLifecycleActionHeartbeat(ctx context.Context, in *autoscaling.RecordLifecycleActionHeartbeatInput, optFns ...func(*autoscaling.Options)) (*autoscaling.RecordLifecycleActionHeartbeatOutput, error) {
+	atomic.AddInt32(&h.heartbeats, 1)
+	return &autoscaling.RecordLifecycleActionHeartbeatOutput{}, nil
+}
+
+func TestTargetPlugin_heartbeatLifecycleAction(t *testing.T) {
+	target := &TargetPlugin{logger: hclog.NewNullLogger()}
+	client := &heartbeatCountingClient{}
+	asg := &autoscalingtypes.AutoScalingGroup{AutoScalingGroupName: aws.String("asg-a")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		target.heartbeatLifecycleAction(ctx, client, asg, "my-hook", []string{"i-1", "i-2"}, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Let a few ticks land, then cancel and make sure the goroutine returns
+	// instead of continuing to heartbeat forever.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("heartbeatLifecycleAction did not return after context cancellation")
+	}
+
+	if got := atomic.LoadInt32(&client.heartbeats); got == 0 {
+		t.Fatalf("expected at least one heartbeat call, got %d", got)
+	}
+
+	countAfterStop := atomic.LoadInt32(&client.heartbeats)
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&client.heartbeats); got != countAfterStop {
+		t.Fatalf("expected no further heartbeats after cancellation, count went from %d to %d", countAfterStop, got)
+	}
+}
+
+func TestLifecycleActionResult(t *testing.T) {
+	if result := lifecycleActionResult(nil); result != lifecycleActionResultContinue {
+		t.Fatalf("expected %s for a successful drain, got %s", lifecycleActionResultContinue, result)
+	}
+	if result := lifecycleActionResult(errors.New("drain failed")); result != lifecycleActionResultAbandon {
+		t.Fatalf("expected %s for a failed drain, got %s", lifecycleActionResultAbandon, result)
+	}
+}
+
+func TestHeartbeatIntervalFromConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]string
+		expected time.Duration
+	}{
+		{
+			name:     "default timeout halved",
+			config:   map[string]string{},
+			expected: time.Duration(configValueHeartbeatTimeoutDefault/2) * time.Second,
+		},
+		{
+			name:     "configured timeout halved",
+			config:   map[string]string{configKeyHeartbeatTimeout: "60"},
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "low timeout floored at minHeartbeatInterval",
+			config:   map[string]string{configKeyHeartbeatTimeout: "1"},
+			expected: minHeartbeatInterval,
+		},
+		{
+			name:     "invalid timeout falls back to default",
+			config:   map[string]string{configKeyHeartbeatTimeout: "not-a-number"},
+			expected: time.Duration(configValueHeartbeatTimeoutDefault/2) * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := heartbeatIntervalFromConfig(tc.config); actual != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
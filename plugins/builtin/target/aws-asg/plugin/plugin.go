@@ -0,0 +1,307 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/helper/nomad"
+	"github.com/hashicorp/nomad-autoscaler/helper/scaleutils"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/plugins/target"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst Target plugins.
+	pluginName = "aws-asg"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyRegion        = "region"
+	configKeyAccessID      = "aws_access_key_id"
+	configKeySecretKey     = "aws_secret_access_key"
+	configKeySessionToken  = "session_token"
+	configKeyASGName       = "asg_name"
+	configKeyClass         = "class"
+	configKeyDrainDeadline = "drain_deadline"
+
+	// configKeyASGNames is a comma-separated list of sibling ASG names. When
+	// supplied, a single scaling action fans out across all of them,
+	// weighted by each ASG's current DesiredCapacity, instead of acting on
+	// a single configKeyASGName.
+	configKeyASGNames = "asg_names"
+
+	// configKeyStrictBounds controls how the plugin behaves when an action
+	// would scale the ASG outside of its configured MinSize/MaxSize. When
+	// set to "true" out-of-bounds actions are rejected outright; otherwise
+	// the requested count is clamped to the nearest bound and a warning is
+	// logged.
+	configKeyStrictBounds = "strict_bounds"
+
+	// configKeyNodeSelector is a comma-separated list of "key=value" EC2 tag
+	// filters used to restrict which instances are eligible as scale-in
+	// termination candidates.
+	configKeyNodeSelector = "node_selector"
+
+	// configKeyPolicyID is the reserved config key the Nomad Autoscaler agent
+	// populates with the ID of the scaling policy driving the current
+	// action. It is used to resolve the target ASG by tag when
+	// configKeyASGName is not supplied.
+	configKeyPolicyID = "nomad_policy_id"
+
+	// configValueASGTagKey is the tag key operators place on an ASG to mark
+	// it as the target of a given Nomad Autoscaler policy, used when
+	// resolving the ASG by tag rather than by explicit name.
+	configValueASGTagKey = "nomad-autoscaler-target"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueRegionDefault = "us-east-1"
+
+	// metaKeys are the Status.Meta keys populated with ASG capacity bounds so
+	// downstream strategy plugins can reason about available headroom.
+	metaKeyMinSize         = "min_size"
+	metaKeyMaxSize         = "max_size"
+	metaKeyDesiredCapacity = "desired_capacity"
+)
+
+var (
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewAWSASGPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: plugins.PluginTypeTarget,
+	}
+)
+
+// Assert that TargetPlugin meets the target.Target interface.
+var _ target.Target = (*TargetPlugin)(nil)
+
+// TargetPlugin is the AWS ASG implementation of the target.Target interface.
+type TargetPlugin struct {
+	config       map[string]string
+	logger       hclog.Logger
+	scaleInUtils *scaleutils.ScaleIn
+
+	// clientsLock guards asgClients/ec2Clients, which are lazily populated
+	// and cached per region so a single plugin instance can drive ASGs
+	// across multiple regions.
+	clientsLock sync.Mutex
+	asgClients  map[string]*autoscaling.Client
+	ec2Clients  map[string]*ec2.Client
+}
+
+// NewAWSASGPlugin returns the AWS ASG implementation of the target.Target
+// interface.
+func NewAWSASGPlugin(log hclog.Logger) *TargetPlugin {
+	return &TargetPlugin{
+		logger:     log,
+		asgClients: make(map[string]*autoscaling.Client),
+		ec2Clients: make(map[string]*ec2.Client),
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Plugin interface.
+func (t *TargetPlugin) SetConfig(config map[string]string) error {
+
+	t.config = config
+
+	utils, err := scaleutils.NewScaleInUtils(nomad.ConfigFromMap(config), t.logger)
+	if err != nil {
+		return err
+	}
+	t.scaleInUtils = utils
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Plugin interface.
+func (t *TargetPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Scale satisfies the Scale function on the target.Target interface. When
+// configKeyASGNames lists multiple sibling ASGs, the requested count is
+// split proportionally across them, weighted by each ASG's current
+// DesiredCapacity.
+func (t *TargetPlugin) Scale(action strategy.Action, config map[string]string) error {
+
+	ctx := context.Background()
+
+	asgClient, ec2Client, err := t.clientsForRegion(ctx, regionFromConfig(config))
+	if err != nil {
+		return err
+	}
+
+	names, err := t.resolveASGNames(ctx, asgClient, config)
+	if err != nil {
+		return err
+	}
+
+	asgs := make([]*autoscalingtypes.AutoScalingGroup, 0, len(names))
+	var totalDesired int64
+
+	for _, name := range names {
+		asg, err := t.describeASG(ctx, asgClient, name)
+		if err != nil {
+			return fmt.Errorf("failed to describe AWS Autoscaling Group %s: %v", name, err)
+		}
+		asgs = append(asgs, asg)
+		totalDesired += *asg.DesiredCapacity
+	}
+
+	targets := weightedTargets(asgs, totalDesired, action.Count)
+	var scaled bool
+
+	for i, asg := range asgs {
+
+		// Respect the ASG's configured MinSize/MaxSize bounds before acting
+		// on the strategy's requested count. Depending on
+		// configKeyStrictBounds the plugin either clamps the count to the
+		// nearest bound (logging a warning) or rejects the action outright.
+		desired, err := t.enforceBounds(asg, targets[i], config)
+		if err != nil {
+			return err
+		}
+
+		num, direction := t.calculateDirection(*asg.DesiredCapacity, desired)
+
+		switch direction {
+		case "in":
+			err = t.scaleIn(ctx, asgClient, ec2Client, asg, num, config)
+		case "out":
+			err = t.scaleOut(ctx, asgClient, asg, num)
+		default:
+			continue
+		}
+
+		scaled = true
+
+		// If we received an error while scaling, format this with an outer
+		// message so its nice for the operators and then return any error
+		// to the caller.
+		if err != nil {
+			return fmt.Errorf("failed to perform scaling action on %s: %v", *asg.AutoScalingGroupName, err)
+		}
+	}
+
+	if !scaled {
+		return fmt.Errorf("scaling not required, ASG(s) already at Autoscaler desired count %v", action.Count)
+	}
+
+	return nil
+}
+
+// Status satisfies the Status function on the target.Target interface. When
+// configKeyASGNames lists multiple sibling ASGs, their individual statuses
+// are combined into a single aggregate Status.
+func (t *TargetPlugin) Status(config map[string]string) (*target.Status, error) {
+
+	ctx := context.Background()
+
+	asgClient, _, err := t.clientsForRegion(ctx, regionFromConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := t.resolveASGNames(ctx, asgClient, config)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := target.Status{Ready: true}
+	var minSize, maxSize, desiredCapacity, lastEvent int64
+
+	for _, name := range names {
+		asg, err := t.describeASG(ctx, asgClient, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe AWS Autoscaling Group %s: %v", name, err)
+		}
+
+		events, err := t.describeActivities(ctx, asgClient, name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe AWS Autoscaling Group activities for %s: %v", name, err)
+		}
+
+		ready := asg.Status == nil
+
+		// If the ASG has scaling activities listed ensure the status takes
+		// into account the most recent activity. Most importantly if the
+		// last event has not finished, the ASG is not ready for scaling.
+		if len(events) > 0 {
+			ready = ready && *events[0].Progress == 100
+			if events[0].EndTime != nil {
+				if et := events[0].EndTime.UnixNano(); et > lastEvent {
+					lastEvent = et
+				}
+			}
+		}
+
+		resp.Ready = resp.Ready && ready
+		resp.Count += *asg.DesiredCapacity
+		minSize += int64(*asg.MinSize)
+		maxSize += int64(*asg.MaxSize)
+		desiredCapacity += *asg.DesiredCapacity
+	}
+
+	resp.Meta = map[string]string{
+		metaKeyMinSize:         strconv.FormatInt(minSize, 10),
+		metaKeyMaxSize:         strconv.FormatInt(maxSize, 10),
+		metaKeyDesiredCapacity: strconv.FormatInt(desiredCapacity, 10),
+	}
+	if lastEvent > 0 {
+		resp.Meta[target.MetaKeyLastEvent] = strconv.FormatInt(lastEvent, 10)
+	}
+
+	return &resp, nil
+}
+
+// enforceBounds checks the strategy's requested count against the ASG's
+// MinSize/MaxSize and either clamps it to the nearest bound (logging a
+// warning) or rejects the action, depending on configKeyStrictBounds.
+func (t *TargetPlugin) enforceBounds(asg *autoscalingtypes.AutoScalingGroup, desired int64, config map[string]string) (int64, error) {
+
+	min, max := int64(*asg.MinSize), int64(*asg.MaxSize)
+
+	if desired >= min && desired <= max {
+		return desired, nil
+	}
+
+	strict := config[configKeyStrictBounds] == "true"
+
+	if strict {
+		return 0, fmt.Errorf("desired count %v outside of ASG bounds [%v, %v]", desired, min, max)
+	}
+
+	clamped := desired
+	if desired < min {
+		clamped = min
+	} else if desired > max {
+		clamped = max
+	}
+
+	t.logger.Warn("desired count outside of ASG bounds, clamping",
+		"desired", desired, "min_size", min, "max_size", max, "clamped", clamped)
+
+	return clamped, nil
+}
+
+func (t *TargetPlugin) calculateDirection(asgDesired, strategyDesired int64) (int64, string) {
+
+	if strategyDesired < asgDesired {
+		return asgDesired - strategyDesired, "in"
+	}
+	if strategyDesired > asgDesired {
+		return strategyDesired, "out"
+	}
+	return 0, ""
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+func TestTargetPlugin_enforceBounds(t *testing.T) {
+	testCases := []struct {
+		name      string
+		minSize   int32
+		maxSize   int32
+		desired   int64
+		config    map[string]string
+		expected  int64
+		expectErr bool
+	}{
+		{
+			name:     "within bounds",
+			minSize:  1,
+			maxSize:  10,
+			desired:  5,
+			config:   map[string]string{},
+			expected: 5,
+		},
+		{
+			name:     "below min is clamped",
+			minSize:  2,
+			maxSize:  10,
+			desired:  0,
+			config:   map[string]string{},
+			expected: 2,
+		},
+		{
+			name:     "above max is clamped",
+			minSize:  1,
+			maxSize:  5,
+			desired:  9,
+			config:   map[string]string{},
+			expected: 5,
+		},
+		{
+			name:      "below min is rejected when strict",
+			minSize:   2,
+			maxSize:   10,
+			desired:   0,
+			config:    map[string]string{configKeyStrictBounds: "true"},
+			expectErr: true,
+		},
+		{
+			name:      "above max is rejected when strict",
+			minSize:   1,
+			maxSize:   5,
+			desired:   9,
+			config:    map[string]string{configKeyStrictBounds: "true"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &TargetPlugin{logger: hclog.NewNullLogger()}
+			asg := &autoscalingtypes.AutoScalingGroup{
+				MinSize: aws.Int32(tc.minSize),
+				MaxSize: aws.Int32(tc.maxSize),
+			}
+
+			actual, err := target.enforceBounds(asg, tc.desired, tc.config)
+
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTargetPlugin_calculateDirection(t *testing.T) {
+	testCases := []struct {
+		name              string
+		asgDesired        int64
+		strategyDesired   int64
+		expectedNum       int64
+		expectedDirection string
+	}{
+		{
+			name:              "scale out",
+			asgDesired:        2,
+			strategyDesired:   5,
+			expectedNum:       5,
+			expectedDirection: "out",
+		},
+		{
+			name:              "scale in",
+			asgDesired:        5,
+			strategyDesired:   2,
+			expectedNum:       3,
+			expectedDirection: "in",
+		},
+		{
+			name:              "no change",
+			asgDesired:        3,
+			strategyDesired:   3,
+			expectedNum:       0,
+			expectedDirection: "",
+		},
+	}
+
+	target := &TargetPlugin{}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			num, direction := target.calculateDirection(tc.asgDesired, tc.strategyDesired)
+			if num != tc.expectedNum || direction != tc.expectedDirection {
+				t.Fatalf("expected (%v, %q), got (%v, %q)", tc.expectedNum, tc.expectedDirection, num, direction)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+// describeInstances returns the EC2 instance details for the passed instance
+// IDs. This enriches the bare ASG membership list with launch template,
+// spot lifecycle, AZ, and tag information used to pick termination
+// candidates.
+func (t *TargetPlugin) describeInstances(ctx context.Context, client *ec2.Client, instanceIDs []string) ([]ec2types.Instance, error) {
+
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	resp, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: instanceIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []ec2types.Instance
+	for _, r := range resp.Reservations {
+		instances = append(instances, r.Instances...)
+	}
+
+	return instances, nil
+}
+
+// selectTerminationCandidates returns up to num instance IDs from the ASG to
+// terminate. Instances are filtered using nodeSelector, a comma-separated
+// list of "key=value" EC2 tag filters, and the remainder are ordered so
+// that spot instances are preferred over on-demand and, within a
+// lifecycle, the oldest instance is preferred.
+func (t *TargetPlugin) selectTerminationCandidates(ctx context.Context, client *ec2.Client, asg *autoscalingtypes.AutoScalingGroup, num int64, nodeSelector string) ([]string, error) {
+
+	ids := make([]string, 0, len(asg.Instances))
+	for _, i := range asg.Instances {
+		ids = append(ids, *i.InstanceId)
+	}
+
+	instances, err := t.describeInstances(ctx, client, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe EC2 instances: %v", err)
+	}
+
+	filters, err := parseNodeSelector(nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]ec2types.Instance, 0, len(instances))
+	for _, i := range instances {
+		if instanceMatchesFilters(i, filters) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	sortTerminationCandidates(candidates)
+
+	if int64(len(candidates)) > num {
+		candidates = candidates[:num]
+	}
+
+	selected := make([]string, 0, len(candidates))
+	for _, i := range candidates {
+		selected = append(selected, *i.InstanceId)
+	}
+
+	return selected, nil
+}
+
+// sortTerminationCandidates orders candidates in place by terminationPriority,
+// tie-breaking on AZ balance: of two instances with equal priority, the one
+// in the AZ currently holding more candidates sorts first, so repeated
+// scale-ins even out the ASG's AZ distribution rather than draining one AZ
+// before any other.
+func sortTerminationCandidates(candidates []ec2types.Instance) {
+
+	azCounts := make(map[string]int, len(candidates))
+	for _, i := range candidates {
+		azCounts[availabilityZone(i)]++
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		pa, pb := terminationPriority(candidates[a]), terminationPriority(candidates[b])
+		if pa != pb {
+			return pa < pb
+		}
+		azA, azB := availabilityZone(candidates[a]), availabilityZone(candidates[b])
+		return azCounts[azA] > azCounts[azB]
+	})
+}
+
+// terminationPriority orders instances for termination: spot instances sort
+// before on-demand, and within a lifecycle the oldest instance (by
+// LaunchTime) sorts first. Lower values are terminated first. AZ balance is
+// applied as a tie-break in selectTerminationCandidates. Instance type and
+// live utilization are intentionally not factored in here: type alone isn't
+// a reliable termination signal without an operator-supplied cost policy,
+// and utilization would require a CloudWatch metrics lookup this plugin
+// doesn't perform; node_selector can be used to steer around either in the
+// meantime.
+func terminationPriority(i ec2types.Instance) int64 {
+	var priority int64
+
+	if i.InstanceLifecycle != ec2types.InstanceLifecycleTypeSpot {
+		priority += 1 << 40
+	}
+	if i.LaunchTime != nil {
+		priority += i.LaunchTime.Unix()
+	}
+
+	return priority
+}
+
+// availabilityZone returns the AZ an instance is placed in, or "" if
+// unknown.
+func availabilityZone(i ec2types.Instance) string {
+	if i.Placement != nil && i.Placement.AvailabilityZone != nil {
+		return *i.Placement.AvailabilityZone
+	}
+	return ""
+}
+
+// parseNodeSelector parses the comma-separated "key=value" tag filter list
+// supplied via the node_selector config key.
+func parseNodeSelector(selector string) (map[string]string, error) {
+
+	filters := map[string]string{}
+	if selector == "" {
+		return filters, nil
+	}
+
+	for _, pair := range strings.Split(selector, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid %s filter %q, expected key=value", configKeyNodeSelector, pair)
+		}
+		filters[kv[0]] = kv[1]
+	}
+
+	return filters, nil
+}
+
+// instanceMatchesFilters returns true when the instance carries every
+// tag key/value pair present in filters.
+func instanceMatchesFilters(i ec2types.Instance, filters map[string]string) bool {
+
+	if len(filters) == 0 {
+		return true
+	}
+
+	tags := make(map[string]string, len(i.Tags))
+	for _, tag := range i.Tags {
+		tags[*tag.Key] = *tag.Value
+	}
+
+	for k, v := range filters {
+		if tags[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
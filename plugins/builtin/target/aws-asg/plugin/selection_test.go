@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestTerminationPriority(t *testing.T) {
+	older := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	spotOlder := ec2types.Instance{InstanceLifecycle: ec2types.InstanceLifecycleTypeSpot, LaunchTime: aws.Time(older)}
+	spotNewer := ec2types.Instance{InstanceLifecycle: ec2types.InstanceLifecycleTypeSpot, LaunchTime: aws.Time(newer)}
+	onDemandOlder := ec2types.Instance{LaunchTime: aws.Time(older)}
+
+	if terminationPriority(spotOlder) >= terminationPriority(spotNewer) {
+		t.Fatalf("expected an older spot instance to sort before a newer spot instance")
+	}
+	if terminationPriority(spotNewer) >= terminationPriority(onDemandOlder) {
+		t.Fatalf("expected any spot instance to sort before an on-demand instance")
+	}
+}
+
+func TestSortTerminationCandidates_AZBalance(t *testing.T) {
+	launch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	instanceIn := func(id, az string) ec2types.Instance {
+		return ec2types.Instance{
+			InstanceId: aws.String(id),
+			LaunchTime: aws.Time(launch),
+			Placement:  &ec2types.Placement{AvailabilityZone: aws.String(az)},
+		}
+	}
+
+	// All three instances share the same termination priority (on-demand,
+	// identical LaunchTime), so AZ balance alone must decide the order.
+	// us-east-1a holds two candidates to us-east-1b's one, so both 1a
+	// instances should sort ahead of the 1b instance.
+	candidates := []ec2types.Instance{
+		instanceIn("i-b1", "us-east-1b"),
+		instanceIn("i-a1", "us-east-1a"),
+		instanceIn("i-a2", "us-east-1a"),
+	}
+
+	sortTerminationCandidates(candidates)
+
+	if az := availabilityZone(candidates[2]); az != "us-east-1b" {
+		t.Fatalf("expected the less represented AZ to sort last, got %s last", az)
+	}
+	for _, i := range candidates[:2] {
+		if az := availabilityZone(i); az != "us-east-1a" {
+			t.Fatalf("expected the more represented AZ to sort first, got %s", az)
+		}
+	}
+}
+
+func TestParseNodeSelector(t *testing.T) {
+	testCases := []struct {
+		name      string
+		selector  string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name:     "empty selector",
+			selector: "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "single filter",
+			selector: "Environment=prod",
+			expected: map[string]string{"Environment": "prod"},
+		},
+		{
+			name:     "multiple filters",
+			selector: "Environment=prod,Team=platform",
+			expected: map[string]string{"Environment": "prod", "Team": "platform"},
+		},
+		{
+			name:      "invalid filter",
+			selector:  "Environment",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseNodeSelector(tc.selector)
+
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+			for k, v := range tc.expected {
+				if actual[k] != v {
+					t.Fatalf("expected %v, got %v", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestInstanceMatchesFilters(t *testing.T) {
+	instance := ec2types.Instance{
+		Tags: []ec2types.Tag{
+			{Key: aws.String("Environment"), Value: aws.String("prod")},
+			{Key: aws.String("Team"), Value: aws.String("platform")},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		filters  map[string]string
+		expected bool
+	}{
+		{
+			name:     "no filters matches everything",
+			filters:  map[string]string{},
+			expected: true,
+		},
+		{
+			name:     "matching filter",
+			filters:  map[string]string{"Environment": "prod"},
+			expected: true,
+		},
+		{
+			name:     "mismatched value",
+			filters:  map[string]string{"Environment": "staging"},
+			expected: false,
+		},
+		{
+			name:     "missing tag",
+			filters:  map[string]string{"Owner": "sre"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := instanceMatchesFilters(instance, tc.filters); actual != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}